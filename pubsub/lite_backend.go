@@ -0,0 +1,348 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sync"
+
+	ipubsub "cloud.google.com/go/internal/pubsub"
+	pb "cloud.google.com/go/pubsub/apiv1/pubsubpb"
+)
+
+// ErrLitePublisherStopped is returned by a LitePublisherBackend for a
+// Publish call made after Stop, or one that races with the backend
+// entering its permanent failure state. Once a partition stream has failed
+// (for example, because the Lite topic was deleted), later Publish calls
+// instead return that stream's error, which the backend keeps as its
+// permanent failure. Unlike the regular Pub/Sub backend, a Lite partition
+// stream cannot simply be retried against a different replica, so such a
+// failure is terminal for that backend instance.
+var ErrLitePublisherStopped = errors.New("pubsub: Lite publisher has permanently stopped")
+
+// ErrLiteBufferOverflow is returned when a Lite partition's local publish
+// buffer is full. Pub/Sub Lite has no server-side flow control signal
+// analogous to the regular service's RESOURCE_EXHAUSTED error, so
+// backpressure is surfaced locally as a buffer overflow instead of an RPC
+// error.
+var ErrLiteBufferOverflow = errors.New("pubsub: Lite publisher buffer overflow")
+
+// defaultLiteMaxOutstanding is the per-partition queue depth used when
+// LitePublisherBackend.MaxOutstandingPerPartition is unset.
+const defaultLiteMaxOutstanding = 1000
+
+// LitePartitionStream is the bidirectional publish stream for a single
+// Pub/Sub Lite partition. Production code supplies an implementation
+// backed by the Lite gRPC service; tests can supply a fake.
+type LitePartitionStream interface {
+	// Send publishes pbMsgs, in order, and returns the cursor offset the
+	// server assigned to each one. An error from Send is always treated
+	// as unretryable: the caller must assume the stream, and the
+	// partition publisher built on it, are no longer usable.
+	Send(ctx context.Context, pbMsgs []*pb.PubsubMessage) (offsets []int64, err error)
+
+	// Close releases resources held by the stream.
+	Close() error
+}
+
+// LiteStreamFactory opens the publish stream for the given partition of a
+// Lite topic.
+type LiteStreamFactory func(ctx context.Context, partition int) (LitePartitionStream, error)
+
+// LitePublisherBackend is a PublisherBackend that targets a Pub/Sub Lite
+// topic instead of a regular Pub/Sub topic, analogous to
+// pscompat.PublisherClient. Install it with Publisher.SetBackend before
+// the first call to Publish.
+//
+// Lite topics are divided into a fixed number of partitions. Messages
+// without an ordering key are spread across all partitions; messages that
+// share an ordering key are routed to the same partition, so that
+// partition's stream order is the delivery order. MessageIDs returned by
+// PublishResult.Get encode the partition and cursor offset Lite assigned
+// the message, rather than an opaque server ID.
+//
+// Each partition is served by a single goroutine that owns that
+// partition's stream and calls Send on it one message at a time, so
+// Publish never races another Publish call over the same stream; Publish
+// itself only enqueues bm and returns, resolving bm.Res once the owning
+// goroutine's Send call completes.
+type LitePublisherBackend struct {
+	// NumPartitions is the number of partitions of the target Lite topic.
+	NumPartitions int
+
+	// NewStream opens the publish stream for a partition. It is called
+	// at most once per partition, the first time a message is routed to
+	// it.
+	NewStream LiteStreamFactory
+
+	// MaxOutstandingPerPartition bounds the number of messages queued per
+	// partition awaiting their turn on that partition's stream, after
+	// which Publish fails locally with ErrLiteBufferOverflow instead of
+	// blocking. Defaults to defaultLiteMaxOutstanding.
+	MaxOutstandingPerPartition int
+
+	mu            sync.Mutex
+	err           error // permanent failure; once set, never cleared
+	partitions    []*litePartitionPublisher
+	stopc         chan struct{} // closed once, when err is first set
+	terminateFunc func(error)   // reports err to Publisher.Done/Err, if set
+	next          int           // round-robin cursor for unkeyed messages
+}
+
+// SetTerminateFunc implements TerminationReporter.
+func (b *LitePublisherBackend) SetTerminateFunc(fn func(error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.terminateFunc = fn
+}
+
+// litePartitionPublisher serializes Send calls for a single partition: a
+// dedicated goroutine (run) is the only reader of queue and the only
+// writer to stream, so two messages routed to the same partition never
+// call Send concurrently.
+type litePartitionPublisher struct {
+	queue   chan *BundledMessage
+	barrier chan chan struct{} // Flush barriers; see run
+	done    chan struct{}      // closed once run has exited
+
+	mu     sync.Mutex
+	stream LitePartitionStream
+}
+
+func newLitePartitionPublisher(b *LitePublisherBackend, partition, capacity int) *litePartitionPublisher {
+	p := &litePartitionPublisher{
+		queue:   make(chan *BundledMessage, capacity),
+		barrier: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go p.run(b, partition)
+	return p
+}
+
+// run is the single writer for p's stream. It exits, after draining
+// whatever was already queued, once b's stopc is closed.
+func (p *litePartitionPublisher) run(b *LitePublisherBackend, partition int) {
+	defer close(p.done)
+	for {
+		select {
+		case bm := <-p.queue:
+			p.send(b, partition, bm)
+		case reached := <-p.barrier:
+			// select chooses at random among ready cases, so bms queued
+			// before this barrier may still be waiting; send them first.
+			// This goroutine is the queue's only reader, so each of the
+			// len(p.queue) bms counted here is still there to receive.
+			for n := len(p.queue); n > 0; n-- {
+				p.send(b, partition, <-p.queue)
+			}
+			close(reached)
+		case <-b.stopc:
+			for {
+				select {
+				case bm := <-p.queue:
+					p.send(b, partition, bm)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *litePartitionPublisher) send(b *LitePublisherBackend, partition int, bm *BundledMessage) {
+	p.mu.Lock()
+	stream := p.stream
+	if stream == nil {
+		s, err := b.NewStream(context.Background(), partition)
+		if err != nil {
+			p.mu.Unlock()
+			b.fail(err)
+			ipubsub.SetPublishResult(bm.Res, "", err)
+			return
+		}
+		stream = s
+		p.stream = s
+	}
+	p.mu.Unlock()
+
+	offsets, err := stream.Send(context.Background(), []*pb.PubsubMessage{{
+		Data:        bm.Msg.Data,
+		Attributes:  bm.Msg.Attributes,
+		OrderingKey: bm.Msg.OrderingKey,
+	}})
+	if err != nil {
+		b.fail(err)
+		ipubsub.SetPublishResult(bm.Res, "", err)
+		return
+	}
+	ipubsub.SetPublishResult(bm.Res, fmt.Sprintf("%d:%d", partition, offsets[0]), nil)
+}
+
+func (p *litePartitionPublisher) closeStream() {
+	<-p.done
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stream != nil {
+		p.stream.Close()
+	}
+}
+
+// Publish implements PublisherBackend. It only enqueues bm onto its
+// partition's queue; the partition's goroutine resolves bm.Res once it
+// gets to it.
+func (b *LitePublisherBackend) Publish(ctx context.Context, bm *BundledMessage) {
+	b.mu.Lock()
+	if b.err != nil {
+		err := b.err
+		b.mu.Unlock()
+		ipubsub.SetPublishResult(bm.Res, "", err)
+		return
+	}
+	if b.stopc == nil {
+		b.stopc = make(chan struct{})
+	}
+	if b.partitions == nil {
+		b.partitions = make([]*litePartitionPublisher, b.numPartitions())
+	}
+	capacity := b.MaxOutstandingPerPartition
+	if capacity <= 0 {
+		capacity = defaultLiteMaxOutstanding
+	}
+	idx := b.routePartition(bm.Msg.OrderingKey)
+	p := b.partitions[idx]
+	if p == nil {
+		p = newLitePartitionPublisher(b, idx, capacity)
+		b.partitions[idx] = p
+	}
+	stopc := b.stopc
+	b.mu.Unlock()
+
+	select {
+	case p.queue <- bm:
+	case <-stopc:
+		ipubsub.SetPublishResult(bm.Res, "", ErrLitePublisherStopped)
+	default:
+		ipubsub.SetPublishResult(bm.Res, "", ErrLiteBufferOverflow)
+	}
+}
+
+// numPartitions is the effective partition count: NumPartitions, or 1 if
+// it's unset, so a zero-value LitePublisherBackend still routes every
+// message to a single valid partition instead of indexing an empty slice.
+func (b *LitePublisherBackend) numPartitions() int {
+	if b.NumPartitions <= 0 {
+		return 1
+	}
+	return b.NumPartitions
+}
+
+// routePartition chooses a partition for orderingKey, the same way on
+// every call so that all messages for a key land on one partition.
+// Messages with an empty key are distributed round-robin across
+// partitions. Callers must hold b.mu.
+func (b *LitePublisherBackend) routePartition(orderingKey string) int {
+	if orderingKey == "" {
+		idx := b.next % b.numPartitions()
+		b.next++
+		return idx
+	}
+	return int(crc32.ChecksumIEEE([]byte(orderingKey))) % b.numPartitions()
+}
+
+// setPermanentErr puts b into its permanent failure state with err, unless
+// it's already in one, and reports whether this call was the one to do so.
+func (b *LitePublisherBackend) setPermanentErr(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return false
+	}
+	b.err = err
+	if b.stopc == nil {
+		b.stopc = make(chan struct{})
+	}
+	close(b.stopc)
+	return true
+}
+
+// fail puts b into its permanent failure state and reports err through
+// terminateFunc, for a stream failure that the backend cannot recover
+// from on its own. It must not be used for an ordinary Stop, which should
+// reject further Publish calls locally without reporting a spurious
+// failure through Publisher.Done/Err.
+func (b *LitePublisherBackend) fail(err error) {
+	if !b.setPermanentErr(err) {
+		return
+	}
+	b.mu.Lock()
+	terminateFunc := b.terminateFunc
+	b.mu.Unlock()
+	if terminateFunc != nil {
+		terminateFunc(err)
+	}
+}
+
+// Flush implements PublisherBackend. It blocks until every message queued
+// on a partition before Flush was called has been passed to that
+// partition's stream; messages queued concurrently with Flush may or may
+// not be waited for.
+func (b *LitePublisherBackend) Flush() {
+	b.mu.Lock()
+	partitions := append([]*litePartitionPublisher(nil), b.partitions...)
+	b.mu.Unlock()
+	for _, p := range partitions {
+		if p == nil {
+			continue
+		}
+		reached := make(chan struct{})
+		select {
+		case p.barrier <- reached:
+			<-reached
+		case <-p.done:
+		}
+	}
+}
+
+// Stop implements PublisherBackend. It puts the backend into its
+// permanent failure state, so any Publish call racing with Stop fails
+// with ErrLitePublisherStopped instead of silently queuing behind a
+// stream that's about to be closed, waits for every partition goroutine
+// to drain the messages it had already queued, and closes every stream
+// that was opened. Unlike fail, Stop does not report ErrLitePublisherStopped
+// through terminateFunc: an ordinary, caller-initiated Stop is not the
+// permanent failure Publisher.Done/Err exists to surface, and must not be
+// indistinguishable from one.
+func (b *LitePublisherBackend) Stop() {
+	b.setPermanentErr(ErrLitePublisherStopped)
+
+	b.mu.Lock()
+	partitions := b.partitions
+	b.mu.Unlock()
+
+	for _, p := range partitions {
+		if p == nil {
+			continue
+		}
+		p.closeStream()
+	}
+}
+
+// ResumePublish implements PublisherBackend. Lite's per-partition stream
+// failures are permanent for the life of a LitePublisherBackend, so there
+// is no paused state to resume from; ResumePublish is a no-op.
+func (b *LitePublisherBackend) ResumePublish(orderingKey string) {}
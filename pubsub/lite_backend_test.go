@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	ipubsub "cloud.google.com/go/internal/pubsub"
+	pb "cloud.google.com/go/pubsub/apiv1/pubsubpb"
+)
+
+func TestLitePublisherBackendRoutePartition(t *testing.T) {
+	b := &LitePublisherBackend{NumPartitions: 4}
+
+	if got, want := b.routePartition("same-key"), b.routePartition("same-key"); got != want {
+		t.Errorf("routePartition is not deterministic for the same key: got %d, want %d", got, want)
+	}
+
+	if got := b.routePartition("any-key"); got < 0 || got >= 4 {
+		t.Errorf("routePartition returned out-of-range partition %d for NumPartitions=4", got)
+	}
+
+	zero := &LitePublisherBackend{}
+	if got := zero.routePartition("k"); got != 0 {
+		t.Errorf("routePartition with NumPartitions<=0: got %d, want 0", got)
+	}
+}
+
+// concurrentStream assigns each sent message the next offset and fails the
+// test if two calls to Send overlap in time, which would indicate the
+// backend let two goroutines write to the same partition's stream at once.
+type concurrentStream struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	active  bool
+	offset  int64
+	nextErr error
+}
+
+func (s *concurrentStream) Send(ctx context.Context, pbMsgs []*pb.PubsubMessage) ([]int64, error) {
+	s.mu.Lock()
+	if s.active {
+		s.mu.Unlock()
+		s.t.Fatal("concurrent Send calls on the same LitePartitionStream")
+	}
+	s.active = true
+	s.mu.Unlock()
+
+	offsets := make([]int64, len(pbMsgs))
+	s.mu.Lock()
+	for i := range pbMsgs {
+		offsets[i] = s.offset
+		s.offset++
+	}
+	err := s.nextErr
+	s.mu.Unlock()
+
+	s.mu.Lock()
+	s.active = false
+	s.mu.Unlock()
+	return offsets, err
+}
+
+func (s *concurrentStream) Close() error { return nil }
+
+func TestLitePublisherBackendPublishZeroNumPartitions(t *testing.T) {
+	stream := &concurrentStream{t: t}
+	b := &LitePublisherBackend{
+		NewStream: func(ctx context.Context, partition int) (LitePartitionStream, error) {
+			return stream, nil
+		},
+	}
+
+	bm := &BundledMessage{Msg: &Message{Data: []byte("x")}, Res: ipubsub.NewPublishResult()}
+	b.Publish(context.Background(), bm)
+	b.Flush()
+	if _, err := bm.Res.Get(context.Background()); err != nil {
+		t.Errorf("PublishResult.Get: %v", err)
+	}
+}
+
+func TestLitePublisherBackendStopDoesNotReportTerminate(t *testing.T) {
+	b := &LitePublisherBackend{NumPartitions: 1}
+
+	var reported error
+	b.SetTerminateFunc(func(err error) { reported = err })
+
+	b.Stop()
+	if reported != nil {
+		t.Errorf("Stop reported %v through terminateFunc, want no report", reported)
+	}
+
+	bm := &BundledMessage{Msg: &Message{Data: []byte("x")}, Res: ipubsub.NewPublishResult()}
+	b.Publish(context.Background(), bm)
+	if _, err := bm.Res.Get(context.Background()); err != ErrLitePublisherStopped {
+		t.Errorf("Publish after Stop: got err %v, want ErrLitePublisherStopped", err)
+	}
+}
+
+func TestLitePublisherBackendPublishDoesNotBlockOrRace(t *testing.T) {
+	stream := &concurrentStream{t: t}
+	b := &LitePublisherBackend{
+		NumPartitions: 1,
+		NewStream: func(ctx context.Context, partition int) (LitePartitionStream, error) {
+			return stream, nil
+		},
+	}
+
+	const n = 50
+	var results []*PublishResult
+	for i := 0; i < n; i++ {
+		bm := &BundledMessage{Msg: &Message{Data: []byte("x")}, Res: ipubsub.NewPublishResult()}
+		b.Publish(context.Background(), bm)
+		results = append(results, bm.Res)
+	}
+	b.Flush()
+	for _, r := range results {
+		if _, err := r.Get(context.Background()); err != nil {
+			t.Errorf("PublishResult.Get: %v", err)
+		}
+	}
+}
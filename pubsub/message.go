@@ -0,0 +1,152 @@
+// Copyright 2016 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"sync"
+	"time"
+)
+
+// Message represents a Pub/Sub message.
+type Message struct {
+	// ID identifies this message. This ID is assigned by the server and is
+	// populated for Messages obtained from a subscription.
+	ID string
+
+	// Data is the actual data in the message.
+	Data []byte
+
+	// Attributes represents the key-value pairs the current message is
+	// labelled with.
+	Attributes map[string]string
+
+	// PublishTime is the time at which the message was published. This is
+	// populated by the server for Messages obtained from a subscription.
+	PublishTime time.Time
+
+	// OrderingKey identifies related messages for which publish order
+	// should be respected.
+	OrderingKey string
+
+	calledDone bool
+
+	// ackh is the server-side ack/nack path used for a regular Pub/Sub
+	// subscription; it is nil for messages delivered outside that path.
+	ackh ackHandler
+
+	// settings is the ReceiveSettings of the subscription the message was
+	// delivered through, used by Nack to find a NackHandler.
+	settings *ReceiveSettings
+
+	// errc, if set, reports an error returned by a NackHandler to the
+	// enclosing call to Subscription.Receive.
+	errc func(error)
+
+	mu sync.Mutex
+}
+
+// ackHandler is the interface a message's delivery mechanism implements to
+// learn the outcome of Message.Ack or Message.Nack.
+type ackHandler interface {
+	ack()
+	nack()
+}
+
+// Ack indicates successful processing of a Message passed to the
+// Subscriber.Receive callback. It should not be called on any other
+// Message value. If message acknowledgement fails, the Message will be
+// redelivered. Client code must call Ack or Nack when finished for each
+// received Message.
+//
+// Ack/Nack MUST be called synchronously from the Subscriber.Receive
+// callback. Calling them from a goroutine may cause issues with the
+// acknowledgement process, such as data races and/or deadlocks.
+func (m *Message) Ack() {
+	m.done(true)
+}
+
+// Nack indicates that the client will not or cannot process a Message
+// passed to the Subscriber.Receive callback. It should not be called on
+// any other Message value. Nack will result in the Message being
+// redelivered more quickly than if it were allowed to expire.
+//
+// If the subscription was configured with a ReceiveSettings.NackHandler,
+// that handler decides what Nack means instead of the server-side NACK
+// described above; see NackHandler for details.
+//
+// Ack/Nack MUST be called synchronously from the Subscriber.Receive
+// callback.
+func (m *Message) Nack() {
+	m.mu.Lock()
+	settings, errc := m.settings, m.errc
+	m.mu.Unlock()
+	if settings != nil && settings.NackHandler != nil {
+		// The NackHandler, not the server, owns what happens next; mark
+		// the message done without calling ackh.nack, so this never
+		// sends a real NACK to the server.
+		if m.markDone() {
+			if err := settings.NackHandler(m); err != nil && errc != nil {
+				errc(err)
+			}
+		}
+		return
+	}
+	m.done(false)
+}
+
+func (m *Message) done(ack bool) bool {
+	m.mu.Lock()
+	if m.calledDone {
+		m.mu.Unlock()
+		return false
+	}
+	m.calledDone = true
+	ackh := m.ackh
+	m.mu.Unlock()
+	if ackh == nil {
+		return true
+	}
+	if ack {
+		ackh.ack()
+	} else {
+		ackh.nack()
+	}
+	return true
+}
+
+// markDone sets calledDone, reporting whether it was not already set,
+// without calling ackh.ack or ackh.nack. It's used by delivery paths,
+// such as a NackHandler, that take over responsibility for the message
+// themselves instead of going through the server-side ack path.
+func (m *Message) markDone() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calledDone {
+		return false
+	}
+	m.calledDone = true
+	return true
+}
+
+// resetDone clears m's done state so Ack/Nack can be called on it again.
+// It exists for a NackHandler, such as RedeliveryNackHandler, that
+// redelivers the same *Message value to the receive callback in-process;
+// without it, the redelivered message's Ack/Nack would see calledDone
+// already set and silently no-op.
+func (m *Message) resetDone() {
+	m.mu.Lock()
+	m.calledDone = false
+	m.mu.Unlock()
+}
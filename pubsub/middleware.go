@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import "context"
+
+// PublishHandler handles a single call to Publisher.Publish, resolving r
+// once the outcome is known. It's the shape both the terminal publish
+// step and every PublishMiddleware operate on.
+type PublishHandler func(ctx context.Context, msg *Message, r *PublishResult)
+
+// PublishMiddleware wraps a PublishHandler with cross-cutting behavior —
+// schema validation, compression, tracing, encryption, outbox
+// deduplication — without forking Publisher.Publish or
+// Publisher.PublishMessageBundle. A middleware can inspect or modify msg,
+// short circuit by resolving r itself and not calling next, or run code
+// after next returns.
+type PublishMiddleware func(next PublishHandler) PublishHandler
+
+// Use registers mws, in order, to run around every future call to
+// t.Publish: the first middleware added is outermost, and the last one
+// added runs immediately before the message is handed to the bundler.
+// Use must be called before the first call to Publish; subsequent calls
+// to Use are no-ops once the chain has been built.
+func (t *Publisher) Use(mws ...PublishMiddleware) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.handler != nil {
+		return
+	}
+	t.middleware = append(t.middleware, mws...)
+}
+
+func chainPublishMiddleware(mws []PublishMiddleware, base PublishHandler) PublishHandler {
+	h := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// ReceiveHandler handles a single message delivered by Subscription.Receive.
+// It's the shape both a user's callback and every ReceiveMiddleware
+// operate on.
+type ReceiveHandler func(ctx context.Context, msg *Message)
+
+// ReceiveMiddleware wraps a ReceiveHandler with cross-cutting behavior —
+// schema validation, decompression, tracing, decryption, deduplication —
+// that should run before and/or after the user's callback.
+//
+// Unlike PublishMiddleware, which Publisher.Use registers directly on a
+// Publisher, ReceiveMiddleware is applied explicitly with ChainReceive,
+// since Subscription.Receive takes its callback as a plain function
+// argument:
+//
+//	err := sub.Receive(ctx, pubsub.ChainReceive(mw1, mw2)(receive))
+type ReceiveMiddleware func(next ReceiveHandler) ReceiveHandler
+
+// ChainReceive composes mws, in order, into a single function that wraps
+// a ReceiveHandler: the first middleware added is outermost, and the last
+// one added runs immediately before the wrapped handler.
+func ChainReceive(mws ...ReceiveMiddleware) func(ReceiveHandler) ReceiveHandler {
+	return func(base ReceiveHandler) ReceiveHandler {
+		h := base
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		return h
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"context"
+	"io"
+	"sync"
+
+	ipubsub "cloud.google.com/go/internal/pubsub"
+)
+
+// gzipEncodingAttr marks a message whose Data was compressed by
+// GzipMessageCompression, so GzipMessageDecompression knows to reverse it.
+const gzipEncodingAttr = "googclient_gzip"
+
+// GzipMessageCompression returns a PublishMiddleware that gzip-compresses
+// each message's Data before it reaches the bundler.
+//
+// This is distinct from PublishSettings.EnableCompression, which
+// compresses an entire batched Publish RPC at the transport level:
+// GzipMessageCompression compresses each message independently, so it
+// also shrinks the copy of Data that sits in the bundler's byte-threshold
+// accounting, and it survives being re-encoded by any backend (including
+// a Lite backend) that doesn't itself support transport compression.
+func GzipMessageCompression() PublishMiddleware {
+	return func(next PublishHandler) PublishHandler {
+		return func(ctx context.Context, msg *Message, r *PublishResult) {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			if _, err := zw.Write(msg.Data); err != nil {
+				ipubsub.SetPublishResult(r, "", err)
+				return
+			}
+			if err := zw.Close(); err != nil {
+				ipubsub.SetPublishResult(r, "", err)
+				return
+			}
+			msg.Data = buf.Bytes()
+			if msg.Attributes == nil {
+				msg.Attributes = map[string]string{}
+			}
+			msg.Attributes[gzipEncodingAttr] = "1"
+			next(ctx, msg, r)
+		}
+	}
+}
+
+// GzipMessageDecompression returns a ReceiveMiddleware that reverses
+// GzipMessageCompression, decompressing msg.Data before it reaches the
+// user's callback.
+func GzipMessageDecompression() ReceiveMiddleware {
+	return func(next ReceiveHandler) ReceiveHandler {
+		return func(ctx context.Context, msg *Message) {
+			if msg.Attributes[gzipEncodingAttr] == "" {
+				next(ctx, msg)
+				return
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(msg.Data))
+			if err != nil {
+				msg.Nack()
+				return
+			}
+			data, err := io.ReadAll(zr)
+			if err != nil {
+				msg.Nack()
+				return
+			}
+			msg.Data = data
+			next(ctx, msg)
+		}
+	}
+}
+
+// TraceContextPropagation returns a PublishMiddleware that stamps attr
+// onto every message with the value carrier returns for the publish
+// context, such as the W3C traceparent header for the span in ctx. Pair
+// it with TraceContextExtraction on the receive side to continue the same
+// trace from the subscriber.
+func TraceContextPropagation(attr string, carrier func(context.Context) string) PublishMiddleware {
+	return func(next PublishHandler) PublishHandler {
+		return func(ctx context.Context, msg *Message, r *PublishResult) {
+			if v := carrier(ctx); v != "" {
+				if msg.Attributes == nil {
+					msg.Attributes = map[string]string{}
+				}
+				msg.Attributes[attr] = v
+			}
+			next(ctx, msg, r)
+		}
+	}
+}
+
+// TraceContextExtraction returns a ReceiveMiddleware that reads attr off
+// the delivered message and derives a new context with restore before
+// calling the next handler, so the user's callback (and anything it
+// calls) runs with the publisher's trace context attached.
+func TraceContextExtraction(attr string, restore func(ctx context.Context, carrier string) context.Context) ReceiveMiddleware {
+	return func(next ReceiveHandler) ReceiveHandler {
+		return func(ctx context.Context, msg *Message) {
+			if v := msg.Attributes[attr]; v != "" {
+				ctx = restore(ctx, v)
+			}
+			next(ctx, msg)
+		}
+	}
+}
+
+// IdempotencyCheck returns a PublishMiddleware that treats a message
+// carrying a previously-seen value of the attr attribute as already
+// published: it resolves the PublishResult successfully, with the empty
+// message ID, without calling next. It's meant for outbox-style
+// publishers that may re-attempt the same logical message after a crash.
+//
+// Keys are remembered in an in-memory set of at most maxKeys entries that
+// evicts the least-recently-added key (seeing a key again does not
+// refresh it); this is
+// best-effort deduplication within a single Publisher's lifetime, not a
+// durable guarantee.
+func IdempotencyCheck(attr string, maxKeys int) PublishMiddleware {
+	seen := newLRUSet(maxKeys)
+	return func(next PublishHandler) PublishHandler {
+		return func(ctx context.Context, msg *Message, r *PublishResult) {
+			key := msg.Attributes[attr]
+			if key != "" && seen.seenBefore(key) {
+				ipubsub.SetPublishResult(r, "", nil)
+				return
+			}
+			next(ctx, msg, r)
+		}
+	}
+}
+
+// lruSet is a fixed-capacity set of strings that evicts the
+// least-recently-added entry once it's full.
+type lruSet struct {
+	cap int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newLRUSet(capacity int) *lruSet {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &lruSet{
+		cap:     capacity,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// seenBefore reports whether key was already present, adding it if not.
+func (s *lruSet) seenBefore(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; ok {
+		return true
+	}
+	if s.order.Len() >= s.cap {
+		oldest := s.order.Front()
+		if oldest != nil {
+			s.order.Remove(oldest)
+			delete(s.entries, oldest.Value.(string))
+		}
+	}
+	s.entries[key] = s.order.PushBack(key)
+	return false
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ipubsub "cloud.google.com/go/internal/pubsub"
+)
+
+func TestGzipMessageCompressionRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	want := []byte("hello, gzip")
+
+	var published *Message
+	pub := GzipMessageCompression()(func(ctx context.Context, msg *Message, r *PublishResult) {
+		published = msg
+	})
+	msg := &Message{Data: append([]byte(nil), want...)}
+	r := ipubsub.NewPublishResult()
+	pub(ctx, msg, r)
+
+	if published.Attributes[gzipEncodingAttr] != "1" {
+		t.Fatalf("Attributes[%q] = %q, want %q", gzipEncodingAttr, published.Attributes[gzipEncodingAttr], "1")
+	}
+	if bytes.Equal(published.Data, want) {
+		t.Fatal("Data was not compressed")
+	}
+
+	var delivered *Message
+	recv := GzipMessageDecompression()(func(ctx context.Context, msg *Message) {
+		delivered = msg
+	})
+	recv(ctx, published)
+
+	if delivered == nil {
+		t.Fatal("decompression middleware did not call next")
+	}
+	if !bytes.Equal(delivered.Data, want) {
+		t.Errorf("Data = %q, want %q", delivered.Data, want)
+	}
+}
+
+func TestGzipMessageDecompressionPassesThroughUncompressed(t *testing.T) {
+	ctx := context.Background()
+	want := []byte("plain data")
+
+	var delivered *Message
+	recv := GzipMessageDecompression()(func(ctx context.Context, msg *Message) {
+		delivered = msg
+	})
+	recv(ctx, &Message{Data: want})
+
+	if delivered == nil {
+		t.Fatal("decompression middleware did not call next for a message with no gzip attribute")
+	}
+	if !bytes.Equal(delivered.Data, want) {
+		t.Errorf("Data = %q, want %q", delivered.Data, want)
+	}
+}
+
+func TestGzipMessageDecompressionNacksOnBadData(t *testing.T) {
+	ctx := context.Background()
+	called := false
+	recv := GzipMessageDecompression()(func(ctx context.Context, msg *Message) {
+		called = true
+	})
+	msg := &Message{
+		Data:       []byte("not actually gzip"),
+		Attributes: map[string]string{gzipEncodingAttr: "1"},
+	}
+	recv(ctx, msg)
+
+	if called {
+		t.Error("next was called for undecodable gzip data")
+	}
+	if !msg.calledDone {
+		t.Error("message was not nacked for undecodable gzip data")
+	}
+}
+
+func TestTraceContextPropagationAndExtraction(t *testing.T) {
+	ctx := context.Background()
+	const attr = "traceparent"
+	const carried = "00-trace-01"
+
+	var published *Message
+	pub := TraceContextPropagation(attr, func(context.Context) string { return carried })(
+		func(ctx context.Context, msg *Message, r *PublishResult) { published = msg })
+	msg := &Message{}
+	r := ipubsub.NewPublishResult()
+	pub(ctx, msg, r)
+
+	if got := published.Attributes[attr]; got != carried {
+		t.Fatalf("Attributes[%q] = %q, want %q", attr, got, carried)
+	}
+
+	type ctxKey struct{}
+	var gotCtx context.Context
+	recv := TraceContextExtraction(attr, func(ctx context.Context, carrier string) context.Context {
+		return context.WithValue(ctx, ctxKey{}, carrier)
+	})(func(ctx context.Context, msg *Message) { gotCtx = ctx })
+	recv(ctx, published)
+
+	if got, _ := gotCtx.Value(ctxKey{}).(string); got != carried {
+		t.Errorf("restored context value = %q, want %q", got, carried)
+	}
+}
+
+func TestTraceContextPropagationSkipsEmptyCarrier(t *testing.T) {
+	ctx := context.Background()
+	const attr = "traceparent"
+
+	var published *Message
+	pub := TraceContextPropagation(attr, func(context.Context) string { return "" })(
+		func(ctx context.Context, msg *Message, r *PublishResult) { published = msg })
+	r := ipubsub.NewPublishResult()
+	pub(ctx, &Message{}, r)
+
+	if _, ok := published.Attributes[attr]; ok {
+		t.Errorf("Attributes[%q] set to %q, want attribute unset", attr, published.Attributes[attr])
+	}
+}
+
+func TestIdempotencyCheckSkipsDuplicateKeys(t *testing.T) {
+	ctx := context.Background()
+	const attr = "idempotency-key"
+	var calls int
+	mw := IdempotencyCheck(attr, 10)(func(ctx context.Context, msg *Message, r *PublishResult) {
+		calls++
+		ipubsub.SetPublishResult(r, "real-id", nil)
+	})
+
+	msg := &Message{Attributes: map[string]string{attr: "k1"}}
+	r1 := ipubsub.NewPublishResult()
+	mw(ctx, msg, r1)
+	if id, err := r1.Get(ctx); err != nil || id != "real-id" {
+		t.Fatalf("first publish: Get() = (%q, %v), want (%q, nil)", id, err, "real-id")
+	}
+	if calls != 1 {
+		t.Fatalf("next called %d times for the first publish, want 1", calls)
+	}
+
+	r2 := ipubsub.NewPublishResult()
+	mw(ctx, &Message{Attributes: map[string]string{attr: "k1"}}, r2)
+	if id, err := r2.Get(ctx); err != nil || id != "" {
+		t.Fatalf("duplicate publish: Get() = (%q, %v), want (\"\", nil)", id, err)
+	}
+	if calls != 1 {
+		t.Errorf("next called %d times total, want 1 (duplicate key should have been skipped)", calls)
+	}
+}
+
+func TestUseChainsMiddlewareOutermostFirst(t *testing.T) {
+	var order []string
+	mw := func(name string) PublishMiddleware {
+		return func(next PublishHandler) PublishHandler {
+			return func(ctx context.Context, msg *Message, r *PublishResult) {
+				order = append(order, name)
+				next(ctx, msg, r)
+			}
+		}
+	}
+	topic := &Publisher{}
+	topic.Use(mw("first"), mw("second"))
+	topic.handler = chainPublishMiddleware(topic.middleware, func(ctx context.Context, msg *Message, r *PublishResult) {
+		order = append(order, "base")
+	})
+
+	r := ipubsub.NewPublishResult()
+	topic.handler(context.Background(), &Message{}, r)
+
+	want := []string{"first", "second", "base"}
+	if len(order) != len(want) {
+		t.Fatalf("call order = %v, want %v", order, want)
+	}
+	for i, name := range want {
+		if order[i] != name {
+			t.Errorf("call order = %v, want %v", order, want)
+			break
+		}
+	}
+}
+
+func TestChainReceiveOrdersOutermostFirst(t *testing.T) {
+	var order []string
+	mw := func(name string) ReceiveMiddleware {
+		return func(next ReceiveHandler) ReceiveHandler {
+			return func(ctx context.Context, msg *Message) {
+				order = append(order, name)
+				next(ctx, msg)
+			}
+		}
+	}
+	h := ChainReceive(mw("first"), mw("second"))(func(ctx context.Context, msg *Message) {
+		order = append(order, "base")
+	})
+	h(context.Background(), &Message{})
+
+	want := []string{"first", "second", "base"}
+	if len(order) != len(want) {
+		t.Fatalf("call order = %v, want %v", order, want)
+	}
+	for i, name := range want {
+		if order[i] != name {
+			t.Errorf("call order = %v, want %v", order, want)
+			break
+		}
+	}
+}
+
+func TestLRUSetSeenBefore(t *testing.T) {
+	s := newLRUSet(2)
+
+	if s.seenBefore("a") {
+		t.Error(`seenBefore("a") = true on first call, want false`)
+	}
+	if !s.seenBefore("a") {
+		t.Error(`seenBefore("a") = false on second call, want true`)
+	}
+
+	if s.seenBefore("b") {
+		t.Error(`seenBefore("b") = true on first call, want false`)
+	}
+	// Capacity is 2 and "a", "b" are both present; adding "c" must evict
+	// the least-recently-added entry, "a".
+	if s.seenBefore("c") {
+		t.Error(`seenBefore("c") = true on first call, want false`)
+	}
+	// Probe "b" before "a": probing the evicted "a" adds it back, which
+	// in turn evicts "b".
+	if !s.seenBefore("b") {
+		t.Error(`"b" should not have been evicted`)
+	}
+	if s.seenBefore("a") {
+		t.Error(`"a" should have been evicted to make room for "c"`)
+	}
+}
+
+func TestNewLRUSetNonPositiveCapacity(t *testing.T) {
+	s := newLRUSet(0)
+	if s.cap != 1 {
+		t.Errorf("newLRUSet(0).cap = %d, want 1", s.cap)
+	}
+}
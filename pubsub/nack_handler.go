@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxTrackedRedeliveryAttempts bounds the number of in-flight messages
+// RedeliveryNackHandler remembers an attempt count for. Once the bound is
+// reached, the least-recently-nacked message's count is forgotten and its
+// next redelivery restarts its backoff from RedeliveryBackoff.Initial;
+// this is a reasonable outcome for a message that's either long acked or
+// still on its first handful of attempts, and keeps a long-running
+// subscriber's memory use bounded regardless of how many distinct
+// messages it ever nacks.
+const maxTrackedRedeliveryAttempts = 10000
+
+// NackHandler is invoked when Message.Nack is called on a message
+// received through a subscription with ReceiveSettings.NackHandler set.
+// A regular Pub/Sub subscription NACKs a message by notifying the server,
+// which makes it eligible for redelivery right away; some delivery modes
+// (most notably the Pub/Sub Lite compatibility layer, where messages
+// cannot be NACKed to the server at all) have no such mechanism, so the
+// client calls NackHandler instead and lets it decide what Nack should
+// mean: ignore it, redeliver the message locally, or give up.
+//
+// Returning a non-nil error from NackHandler terminates the enclosing
+// call to Subscription.Receive with that error.
+//
+// When ReceiveSettings.NackHandler is set, per-message ack deadlines
+// (ReceiveSettings.MaxExtension and friends) become a no-op: there is no
+// server-side lease for the client to extend.
+type NackHandler func(msg *Message) error
+
+// TerminateNackHandler is a NackHandler that terminates Receive, so that
+// calling Message.Nack() somewhere NACK isn't supported fails loudly
+// instead of being silently swallowed. It is not installed automatically:
+// it takes effect only when assigned to ReceiveSettings.NackHandler, and a
+// subscription with no NackHandler set sends Nack to the server as usual.
+func TerminateNackHandler(msg *Message) error {
+	return fmt.Errorf("pubsub: Message.Nack is not supported by this subscription; set ReceiveSettings.NackHandler to handle it (message ID %q)", msg.ID)
+}
+
+// RedeliveryBackoff configures the delay RedeliveryNackHandler waits
+// before redelivering a nacked message.
+type RedeliveryBackoff struct {
+	// Initial is the delay before the first redelivery of a message.
+	Initial time.Duration
+
+	// Max caps the delay. The delay doubles after each successive nack of
+	// the same message, up to this value.
+	Max time.Duration
+}
+
+func (b RedeliveryBackoff) delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if b.Max > 0 && d >= b.Max {
+			return b.Max
+		}
+	}
+	return d
+}
+
+// RedeliveryNackHandler is a NackHandler that redelivers nacked messages
+// to the original receive callback in-process, after an exponentially
+// increasing backoff, instead of relying on a server-side NACK. Use it
+// with subscriptions in NACK-less compatibility mode:
+//
+//	h := pubsub.NewRedeliveryNackHandler(ctx, receive, pubsub.RedeliveryBackoff{
+//		Initial: 500 * time.Millisecond,
+//		Max:     time.Minute,
+//	})
+//	sub.ReceiveSettings.NackHandler = h.Nack
+//	err := sub.Receive(ctx, receive)
+//
+// The ctx passed to NewRedeliveryNackHandler is used for redelivered
+// calls to receive; it should be the same ctx passed to Receive; a
+// message redelivery scheduled after that ctx is done is dropped.
+type RedeliveryNackHandler struct {
+	ctx     context.Context
+	receive func(context.Context, *Message)
+	backoff RedeliveryBackoff
+
+	mu       sync.Mutex
+	attempts map[string]int
+	order    *list.List
+	elems    map[string]*list.Element
+}
+
+// NewRedeliveryNackHandler creates a RedeliveryNackHandler that redelivers
+// nacked messages to receive, the same callback passed to
+// Subscription.Receive, after backoff.
+func NewRedeliveryNackHandler(ctx context.Context, receive func(context.Context, *Message), backoff RedeliveryBackoff) *RedeliveryNackHandler {
+	return &RedeliveryNackHandler{
+		ctx:      ctx,
+		receive:  receive,
+		backoff:  backoff,
+		attempts: map[string]int{},
+		order:    list.New(),
+		elems:    map[string]*list.Element{},
+	}
+}
+
+// Nack implements NackHandler. Assign it to ReceiveSettings.NackHandler.
+func (h *RedeliveryNackHandler) Nack(msg *Message) error {
+	h.mu.Lock()
+	attempt := h.attempts[msg.ID]
+	h.attempts[msg.ID] = attempt + 1
+	h.touch(msg.ID)
+	h.mu.Unlock()
+
+	time.AfterFunc(h.backoff.delay(attempt), func() {
+		if h.ctx.Err() != nil {
+			return
+		}
+		msg.resetDone()
+		h.receive(h.ctx, msg)
+	})
+	return nil
+}
+
+// touch records msg.ID as the most-recently-nacked message, evicting the
+// least-recently-nacked one's attempt count once more than
+// maxTrackedRedeliveryAttempts are tracked. Callers must hold h.mu.
+func (h *RedeliveryNackHandler) touch(id string) {
+	if e, ok := h.elems[id]; ok {
+		h.order.MoveToBack(e)
+		return
+	}
+	h.elems[id] = h.order.PushBack(id)
+	if h.order.Len() <= maxTrackedRedeliveryAttempts {
+		return
+	}
+	oldest := h.order.Front()
+	h.order.Remove(oldest)
+	evicted := oldest.Value.(string)
+	delete(h.elems, evicted)
+	delete(h.attempts, evicted)
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedeliveryBackoffDelay(t *testing.T) {
+	b := RedeliveryBackoff{Initial: time.Second, Max: 8 * time.Second}
+	for _, tc := range []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{10, 8 * time.Second}, // capped
+	} {
+		if got := b.delay(tc.attempt); got != tc.want {
+			t.Errorf("delay(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestRedeliveryBackoffDelayZeroMax(t *testing.T) {
+	// A zero Max must not act as a zero cap (every attempt after the
+	// first getting a 0 delay); it should be treated as uncapped instead.
+	b := RedeliveryBackoff{Initial: time.Second}
+	if got, want := b.delay(3), 8*time.Second; got != want {
+		t.Errorf("delay(3) with zero Max = %v, want %v", got, want)
+	}
+}
+
+func TestRedeliveryNackHandlerBoundsAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := NewRedeliveryNackHandler(ctx, func(context.Context, *Message) {}, RedeliveryBackoff{
+		Initial: time.Hour, // long enough that AfterFunc never actually fires during the test
+		Max:     time.Hour,
+	})
+
+	for i := 0; i < maxTrackedRedeliveryAttempts+10; i++ {
+		h.Nack(&Message{ID: string(rune(i))})
+	}
+
+	h.mu.Lock()
+	n := len(h.attempts)
+	h.mu.Unlock()
+	if n > maxTrackedRedeliveryAttempts {
+		t.Errorf("tracked %d attempt entries, want at most %d", n, maxTrackedRedeliveryAttempts)
+	}
+}
+
+type fakeAckHandler struct {
+	acked, nacked bool
+}
+
+func (h *fakeAckHandler) ack()  { h.acked = true }
+func (h *fakeAckHandler) nack() { h.nacked = true }
+
+func TestNackWithNackHandlerDoesNotNackServerSide(t *testing.T) {
+	ackh := &fakeAckHandler{}
+	called := false
+	msg := &Message{
+		ackh: ackh,
+		settings: &ReceiveSettings{
+			NackHandler: func(*Message) error {
+				called = true
+				return nil
+			},
+		},
+	}
+
+	msg.Nack()
+
+	if !called {
+		t.Error("NackHandler was not invoked")
+	}
+	if ackh.nacked {
+		t.Error("ackh.nack was called; NackHandler mode must not send a server-side NACK")
+	}
+	if ackh.acked {
+		t.Error("ackh.ack was called")
+	}
+}
+
+func TestRedeliveryNackHandlerRedeliversMoreThanOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	done := make(chan struct{})
+	var h *RedeliveryNackHandler
+	receive := func(ctx context.Context, msg *Message) {
+		msg.settings = &ReceiveSettings{NackHandler: h.Nack}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			msg.Nack()
+			return
+		}
+		close(done)
+	}
+	h = NewRedeliveryNackHandler(ctx, receive, RedeliveryBackoff{
+		Initial: time.Millisecond,
+		Max:     time.Millisecond,
+	})
+
+	msg := &Message{ID: "m1", settings: &ReceiveSettings{NackHandler: h.Nack}}
+	msg.Nack()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("receive was called %d times, want 3: redelivery stopped after the first nack", atomic.LoadInt32(&calls))
+	}
+}
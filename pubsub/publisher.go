@@ -71,9 +71,14 @@ type Publisher struct {
 	// first call to Publish. The default is DefaultPublishSettings.
 	PublishSettings PublishSettings
 
-	mu        sync.RWMutex
-	stopped   bool
-	scheduler *scheduler.PublishScheduler
+	mu         sync.RWMutex
+	stopped    bool
+	scheduler  PublishScheduler
+	backend    PublisherBackend
+	middleware []PublishMiddleware
+	handler    PublishHandler
+	done       chan struct{}
+	err        error
 
 	flowController
 
@@ -245,33 +250,41 @@ func (t *Publisher) Publish(ctx context.Context, msg *Message) *PublishResult {
 		return r
 	}
 
-	// Calculate the size of the encoded proto message by accounting
-	// for the length of an individual PubSubMessage and Data/Attributes field.
-	msgSize := proto.Size(&pb.PubsubMessage{
-		Data:        msg.Data,
-		Attributes:  msg.Attributes,
-		OrderingKey: msg.OrderingKey,
-	})
-
-	t.initBundler()
+	t.initBackend()
 	t.mu.RLock()
-	defer t.mu.RUnlock()
-	if t.stopped {
+	handler := t.handler
+	stopped := t.stopped
+	t.mu.RUnlock()
+	if stopped || handler == nil {
 		ipubsub.SetPublishResult(r, "", ErrTopicStopped)
 		return r
 	}
+	handler(ctx, msg, r)
+	return r
+}
 
-	if err := t.flowController.acquire(ctx, msgSize); err != nil {
-		t.scheduler.Pause(msg.OrderingKey)
-		ipubsub.SetPublishResult(r, "", err)
-		return r
+// publishBase is the innermost PublishHandler: it wraps msg for the
+// backend's Publish and is the handler every PublishMiddleware ultimately
+// wraps.
+func (t *Publisher) publishBase(ctx context.Context, msg *Message, r *PublishResult) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.stopped {
+		ipubsub.SetPublishResult(r, "", ErrTopicStopped)
+		return
 	}
-	err = t.scheduler.Add(msg.OrderingKey, &bundledMessage{msg, r, msgSize}, msgSize)
-	if err != nil {
-		t.scheduler.Pause(msg.OrderingKey)
-		ipubsub.SetPublishResult(r, "", err)
+	if t.err != nil {
+		ipubsub.SetPublishResult(r, "", t.err)
+		return
 	}
-	return r
+	// Calculate the size of the encoded proto message by accounting
+	// for the length of an individual PubSubMessage and Data/Attributes field.
+	msgSize := proto.Size(&pb.PubsubMessage{
+		Data:        msg.Data,
+		Attributes:  msg.Attributes,
+		OrderingKey: msg.OrderingKey,
+	})
+	t.backend.Publish(ctx, &BundledMessage{Msg: msg, Res: r, Size: msgSize})
 }
 
 // Stop sends all remaining published messages and stop goroutines created for handling
@@ -279,32 +292,47 @@ func (t *Publisher) Publish(ctx context.Context, msg *Message) *PublishResult {
 // failed to be sent.
 func (t *Publisher) Stop() {
 	t.mu.Lock()
-	noop := t.stopped || t.scheduler == nil
+	noop := t.stopped || t.backend == nil
 	t.stopped = true
 	t.mu.Unlock()
 	if noop {
 		return
 	}
-	t.scheduler.FlushAndStop()
+	t.backend.Stop()
 }
 
 // Flush blocks until all remaining messages are sent.
 func (t *Publisher) Flush() {
-	if t.stopped || t.scheduler == nil {
+	t.mu.RLock()
+	backend := t.backend
+	stopped := t.stopped
+	t.mu.RUnlock()
+	if stopped || backend == nil {
 		return
 	}
-	t.scheduler.Flush()
+	backend.Flush()
 }
 
-type bundledMessage struct {
-	msg  *Message
-	res  *PublishResult
-	size int
+// BundledMessage pairs a published Message with the PublishResult it
+// resolves and the encoded proto size Publisher used for its flow-control
+// and batching accounting. It's the type PublisherBackend.Publish and the
+// built-in PublishScheduler implementations (AIMDScheduler,
+// PartitionStickyScheduler) operate on; it's exported so that an external
+// PublisherBackend or PublishScheduler can be written outside package
+// pubsub.
+type BundledMessage struct {
+	Msg  *Message
+	Res  *PublishResult
+	Size int
 }
 
-func (t *Publisher) initBundler() {
+// initBackend installs the default, unary-RPC-based PublisherBackend the
+// first time it's needed (if a different backend wasn't already installed
+// with SetBackend), and builds the PublishMiddleware chain registered with
+// Use around publishBase.
+func (t *Publisher) initBackend() {
 	t.mu.RLock()
-	noop := t.stopped || t.scheduler != nil
+	noop := t.stopped || t.handler != nil
 	t.mu.RUnlock()
 	if noop {
 		return
@@ -312,37 +340,22 @@ func (t *Publisher) initBundler() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	// Must re-check, since we released the lock.
-	if t.stopped || t.scheduler != nil {
+	if t.stopped || t.handler != nil {
 		return
 	}
-
-	timeout := t.PublishSettings.Timeout
-
-	workers := t.PublishSettings.NumGoroutines
-	// Unless overridden, allow many goroutines per CPU to call the Publish RPC
-	// concurrently. The default value was determined via extensive load
-	// testing (see the loadtest subdirectory).
-	if t.PublishSettings.NumGoroutines == 0 {
-		workers = 25 * runtime.GOMAXPROCS(0)
+	if t.backend == nil {
+		t.initScheduler()
+		t.backend = &rpcPublisherBackend{t: t}
 	}
-
-	t.scheduler = scheduler.NewPublishScheduler(workers, func(bundle interface{}) {
-		// TODO(jba): use a context detached from the one passed to NewClient.
-		ctx := context.TODO()
-		if timeout != 0 {
-			var cancel func()
-			ctx, cancel = context.WithTimeout(ctx, timeout)
-			defer cancel()
-		}
-		t.publishMessageBundle(ctx, bundle.([]*bundledMessage))
-	})
-	t.scheduler.DelayThreshold = t.PublishSettings.DelayThreshold
-	t.scheduler.BundleCountThreshold = t.PublishSettings.CountThreshold
-	if t.scheduler.BundleCountThreshold > MaxPublishRequestCount {
-		t.scheduler.BundleCountThreshold = MaxPublishRequestCount
+	t.handler = chainPublishMiddleware(t.middleware, t.publishBase)
+	if tr, ok := t.backend.(TerminationReporter); ok {
+		tr.SetTerminateFunc(t.terminate)
 	}
-	t.scheduler.BundleByteThreshold = t.PublishSettings.ByteThreshold
+}
 
+// initScheduler builds the bundler and flow controller used by the default
+// PublisherBackend. Callers must hold t.mu.
+func (t *Publisher) initScheduler() {
 	fcs := DefaultPublishSettings.FlowControlSettings
 	fcs.LimitExceededBehavior = t.PublishSettings.FlowControlSettings.LimitExceededBehavior
 	if t.PublishSettings.FlowControlSettings.MaxOutstandingBytes > 0 {
@@ -361,15 +374,54 @@ func (t *Publisher) initBundler() {
 
 	t.flowController = newTopicFlowController(fcs)
 
+	// The scheduler reads PublishSettings.BufferedByteLimit, which the
+	// flow-control setup above may have just overridden, so it must be
+	// built after.
+	if t.scheduler == nil {
+		t.scheduler = t.newDefaultScheduler()
+	}
+}
+
+// newDefaultScheduler builds the bundler-based PublishScheduler used
+// unless a different one was installed with SetScheduler.
+func (t *Publisher) newDefaultScheduler() PublishScheduler {
+	timeout := t.PublishSettings.Timeout
+
+	workers := t.PublishSettings.NumGoroutines
+	// Unless overridden, allow many goroutines per CPU to call the Publish RPC
+	// concurrently. The default value was determined via extensive load
+	// testing (see the loadtest subdirectory).
+	if t.PublishSettings.NumGoroutines == 0 {
+		workers = 25 * runtime.GOMAXPROCS(0)
+	}
+
+	s := scheduler.NewPublishScheduler(workers, func(bundle interface{}) {
+		// TODO(jba): use a context detached from the one passed to NewClient.
+		ctx := context.TODO()
+		if timeout != 0 {
+			var cancel func()
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		t.PublishMessageBundle(ctx, bundle.([]*BundledMessage))
+	})
+	s.DelayThreshold = t.PublishSettings.DelayThreshold
+	s.BundleCountThreshold = t.PublishSettings.CountThreshold
+	if s.BundleCountThreshold > MaxPublishRequestCount {
+		s.BundleCountThreshold = MaxPublishRequestCount
+	}
+	s.BundleByteThreshold = t.PublishSettings.ByteThreshold
+
 	bufferedByteLimit := DefaultPublishSettings.BufferedByteLimit
 	if t.PublishSettings.BufferedByteLimit > 0 {
 		bufferedByteLimit = t.PublishSettings.BufferedByteLimit
 	}
-	t.scheduler.BufferedByteLimit = bufferedByteLimit
+	s.BufferedByteLimit = bufferedByteLimit
 
 	// Calculate the max limit of a single bundle. 5 comes from the number of bytes
 	// needed to be reserved for encoding the PubsubMessage repeated field.
-	t.scheduler.BundleByteLimit = MaxPublishRequestBytes - calcFieldSizeString(t.name) - 5
+	s.BundleByteLimit = MaxPublishRequestBytes - calcFieldSizeString(t.name) - 5
+	return s
 }
 
 // ErrPublishingPaused is a custom error indicating that the publish paused for the specified ordering key.
@@ -382,23 +434,30 @@ func (e ErrPublishingPaused) Error() string {
 
 }
 
-func (t *Publisher) publishMessageBundle(ctx context.Context, bms []*bundledMessage) {
+// PublishMessageBundle sends bms as a single unary Publish RPC on t's
+// topic, the same RPC the default PublisherBackend issues for a bundle the
+// default scheduler flushes. It's exported so that a PublishScheduler
+// installed with SetScheduler, which cannot otherwise reach the proto
+// encoding, compression, retry, and flow-control-release logic a real
+// publish needs, can use it as its Publish callback instead of
+// reimplementing that logic; see AIMDScheduler and PartitionStickyScheduler.
+func (t *Publisher) PublishMessageBundle(ctx context.Context, bms []*BundledMessage) {
 	ctx, err := tag.New(ctx, tag.Insert(keyStatus, "OK"), tag.Upsert(keyTopic, t.name))
 	if err != nil {
-		log.Printf("pubsub: cannot create context with tag in publishMessageBundle: %v", err)
+		log.Printf("pubsub: cannot create context with tag in PublishMessageBundle: %v", err)
 	}
 	pbMsgs := make([]*pb.PubsubMessage, len(bms))
 	var orderingKey string
 	batchSize := 0
 	for i, bm := range bms {
-		orderingKey = bm.msg.OrderingKey
+		orderingKey = bm.Msg.OrderingKey
 		pbMsgs[i] = &pb.PubsubMessage{
-			Data:        bm.msg.Data,
-			Attributes:  bm.msg.Attributes,
-			OrderingKey: bm.msg.OrderingKey,
+			Data:        bm.Msg.Data,
+			Attributes:  bm.Msg.Attributes,
+			OrderingKey: bm.Msg.OrderingKey,
 		}
 		batchSize = batchSize + proto.Size(pbMsgs[i])
-		bm.msg = nil // release bm.msg for GC
+		bm.Msg = nil // release bm.Msg for GC
 	}
 	var res *pb.PublishResponse
 	start := time.Now()
@@ -432,16 +491,19 @@ func (t *Publisher) publishMessageBundle(ctx context.Context, bms []*bundledMess
 		// using same stats.Record() call as success case.
 		ctx, _ = tag.New(ctx, tag.Upsert(keyStatus, "ERROR"),
 			tag.Upsert(keyError, err.Error()))
+		if isTerminalPublishError(err) {
+			t.terminate(err)
+		}
 	}
 	stats.Record(ctx,
 		PublishLatency.M(float64(end.Sub(start)/time.Millisecond)),
 		PublishedMessages.M(int64(len(bms))))
 	for i, bm := range bms {
-		t.flowController.release(ctx, bm.size)
+		t.flowController.release(ctx, bm.Size)
 		if err != nil {
-			ipubsub.SetPublishResult(bm.res, "", err)
+			ipubsub.SetPublishResult(bm.Res, "", err)
 		} else {
-			ipubsub.SetPublishResult(bm.res, res.MessageIds[i], nil)
+			ipubsub.SetPublishResult(bm.Res, res.MessageIds[i], nil)
 		}
 	}
 }
@@ -452,11 +514,25 @@ func (t *Publisher) publishMessageBundle(ctx context.Context, bms []*bundledMess
 // out of order.
 func (t *Publisher) ResumePublish(orderingKey string) {
 	t.mu.RLock()
-	noop := t.scheduler == nil
+	backend := t.backend
 	t.mu.RUnlock()
-	if noop {
+	if backend == nil {
 		return
 	}
+	backend.ResumePublish(orderingKey)
+}
 
-	t.scheduler.Resume(orderingKey)
+// publishBundled is the default PublisherBackend's implementation of
+// Publish: it applies flow control and hands bm off to the scheduler to be
+// bundled with other messages sharing the same ordering key.
+func (t *Publisher) publishBundled(ctx context.Context, bm *BundledMessage) {
+	if err := t.flowController.acquire(ctx, bm.Size); err != nil {
+		t.scheduler.Pause(bm.Msg.OrderingKey)
+		ipubsub.SetPublishResult(bm.Res, "", err)
+		return
+	}
+	if err := t.scheduler.Add(bm.Msg.OrderingKey, bm, bm.Size); err != nil {
+		t.scheduler.Pause(bm.Msg.OrderingKey)
+		ipubsub.SetPublishResult(bm.Res, "", err)
+	}
 }
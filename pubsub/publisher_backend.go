@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import "context"
+
+// PublisherBackend is the transport used by a Publisher to deliver
+// messages. The default backend, installed automatically on the first
+// call to Publisher.Publish, bundles messages and sends them with unary
+// Publish RPCs against the Pub/Sub service. An alternative backend, such
+// as one built on Pub/Sub Lite's bidirectional streaming API (see
+// LitePublisherBackend), can be installed with Publisher.SetBackend
+// before the first call to Publish.
+//
+// A PublisherBackend must be safe for concurrent use by multiple
+// goroutines.
+type PublisherBackend interface {
+	// Publish delivers bm asynchronously, resolving bm.Res once the
+	// outcome (a server-assigned message ID, or an error) is known.
+	// Publish must not block waiting for that outcome.
+	Publish(ctx context.Context, bm *BundledMessage)
+
+	// Flush blocks until every message passed to Publish so far has been
+	// sent.
+	Flush()
+
+	// Stop flushes outstanding messages and releases any resources, such
+	// as background goroutines or open streams, owned by the backend.
+	Stop()
+
+	// ResumePublish resumes publishing for an ordering key that was
+	// paused after a previous publish error.
+	ResumePublish(orderingKey string)
+}
+
+// SetBackend installs an alternative PublisherBackend for t, in place of
+// the default backend that sends unary Publish RPCs. It must be called
+// before the first call to t.Publish; once a backend has been installed,
+// either by SetBackend or lazily by the first Publish call, later calls to
+// SetBackend are no-ops.
+//
+// This is the extension point Pub/Sub Lite support is built on: install a
+// LitePublisherBackend to target a Lite topic while keeping the same
+// Publish/PublishResult contract used for regular Pub/Sub topics.
+func (t *Publisher) SetBackend(b PublisherBackend) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped || t.backend != nil {
+		return
+	}
+	t.backend = b
+}
+
+// rpcPublisherBackend is the default PublisherBackend. It delegates to the
+// scheduler and flow controller already maintained on Publisher, so it
+// adds no state of its own.
+type rpcPublisherBackend struct {
+	t *Publisher
+}
+
+func (b *rpcPublisherBackend) Publish(ctx context.Context, bm *BundledMessage) {
+	b.t.publishBundled(ctx, bm)
+}
+
+func (b *rpcPublisherBackend) Flush() { b.t.scheduler.Flush() }
+
+func (b *rpcPublisherBackend) Stop() { b.t.scheduler.FlushAndStop() }
+
+func (b *rpcPublisherBackend) ResumePublish(orderingKey string) {
+	b.t.scheduler.Resume(orderingKey)
+}
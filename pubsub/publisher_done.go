@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Done returns a channel that's closed once t has entered a permanent
+// failure state: for example, after repeated auth failures, after the
+// topic has been deleted, or after a backend (such as a
+// LitePublisherBackend) reports its own unretryable error. Call Err once
+// Done is closed to retrieve that error.
+//
+// Besides failing the affected PublishResults, such a failure is reported
+// once for the whole Publisher through Done and Err. Publish calls made
+// after Done is closed still return a non-nil PublishResult, but it
+// resolves immediately with Err().
+func (t *Publisher) Done() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done == nil {
+		t.done = make(chan struct{})
+	}
+	return t.done
+}
+
+// Err returns the error that caused t to terminate, or nil if t hasn't
+// (yet).
+func (t *Publisher) Err() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.err
+}
+
+// terminate puts t into its permanent failure state, closing the channel
+// returned by Done. Only the first call has an effect.
+func (t *Publisher) terminate(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	t.err = err
+	if t.done == nil {
+		t.done = make(chan struct{})
+	}
+	close(t.done)
+}
+
+// TerminationReporter is implemented by a PublisherBackend that can detect
+// its own permanent failures, such as a Pub/Sub Lite partition stream
+// that failed with an unretryable error, and surface them through
+// Publisher.Done and Publisher.Err. Publisher calls SetTerminateFunc once,
+// on the first call to Publish, for an installed backend that implements
+// it.
+type TerminationReporter interface {
+	// SetTerminateFunc registers fn to be called, at most once, when the
+	// backend enters a permanent failure state.
+	SetTerminateFunc(fn func(error))
+}
+
+// isTerminalPublishError reports whether err, returned from a Publish RPC
+// after the client's own retry policy gave up on it, indicates a
+// condition that retrying the bundle (or any future bundle against the
+// same topic) can't fix: the topic is gone, or the caller's credentials
+// are no longer good for it.
+func isTerminalPublishError(err error) bool {
+	switch status.Code(err) {
+	case codes.NotFound, codes.PermissionDenied, codes.Unauthenticated:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+// PublishScheduler batches messages passed to Publisher.Publish and
+// arranges for them to be sent, the same role
+// cloud.google.com/go/pubsub/internal/scheduler.PublishScheduler plays
+// for the default PublisherBackend. Install an alternative implementation
+// with Publisher.SetScheduler to change how messages are batched without
+// forking Publish or Publisher.PublishMessageBundle; see AIMDScheduler and
+// PartitionStickyScheduler for two built-in alternatives.
+//
+// A PublishScheduler must be safe for concurrent use by multiple
+// goroutines.
+type PublishScheduler interface {
+	// Add enqueues bundle (a *BundledMessage, in every call Publisher
+	// makes) of size bytes under orderingKey. Messages added under the
+	// same non-empty orderingKey must be delivered, in order, to a
+	// single call of the scheduler's publish callback at a time; an
+	// empty orderingKey has no such constraint.
+	Add(orderingKey string, bundle interface{}, size int) error
+
+	// Pause stops accepting messages for orderingKey until Resume is
+	// called. The default backend calls this after a publish error, to
+	// avoid publishing messages for that key out of order.
+	Pause(orderingKey string)
+
+	// Resume undoes a previous Pause.
+	Resume(orderingKey string)
+
+	// IsPaused reports whether orderingKey is currently paused.
+	IsPaused(orderingKey string) bool
+
+	// Flush blocks until every bundle added so far has been handed to
+	// the publish callback.
+	Flush()
+
+	// FlushAndStop flushes and then releases any resources, such as
+	// goroutines or timers, the scheduler holds.
+	FlushAndStop()
+}
+
+// SetScheduler installs an alternative PublishScheduler for t, in place
+// of the default bundler. It must be called before the first call to
+// Publish; once a scheduler has been installed, either by SetScheduler or
+// lazily by the first Publish call, later calls to SetScheduler are
+// no-ops.
+func (t *Publisher) SetScheduler(s PublishScheduler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped || t.scheduler != nil {
+		return
+	}
+	t.scheduler = s
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AIMDScheduler is a PublishScheduler that adapts its batching delay to
+// observed publish latency instead of using a fixed DelayThreshold: when
+// latency is comfortably under Target, the delay is increased a step at a
+// time (favoring bigger batches); the first bundle whose latency crosses
+// Target halves the delay immediately (favoring quicker delivery). Pair
+// it with Publisher.SetScheduler.
+//
+// Publish is called with every bundle AIMDScheduler flushes; it should
+// call Publisher.PublishMessageBundle (or equivalent) and is responsible
+// for timing the call and reporting the latency back, via RecordLatency,
+// so the scheduler can adjust.
+type AIMDScheduler struct {
+	// Publish sends a bundle of messages sharing a single ordering key
+	// (or none).
+	Publish func(bundle []*BundledMessage)
+
+	// CountThreshold flushes a non-empty bundle once it reaches this
+	// many messages, regardless of the current delay.
+	CountThreshold int
+
+	// ByteThreshold flushes a non-empty bundle once it reaches this many
+	// bytes, regardless of the current delay.
+	ByteThreshold int
+
+	// MinDelay and MaxDelay bound the adaptive delay.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+
+	// Target is the publish latency AIMDScheduler tries to stay under.
+	Target time.Duration
+
+	// AdditiveStep is how much the delay grows after a bundle publishes
+	// faster than Target.
+	AdditiveStep time.Duration
+
+	mu      sync.Mutex
+	delay   time.Duration
+	queues  map[string]*aimdQueue
+	stopc   chan struct{}  // closed by FlushAndStop to tell queue workers to exit
+	stopped bool           // guards against closing stopc twice
+	wg      sync.WaitGroup // outstanding calls to Publish dispatched by flushLocked
+	workers sync.WaitGroup // running per-queue worker goroutines
+}
+
+type aimdQueue struct {
+	mu           sync.Mutex
+	pending      []*BundledMessage
+	pendingBytes int
+	timer        *time.Timer
+	paused       bool
+	flushc       chan []*BundledMessage // flushed bundles, handed off to this queue's worker
+}
+
+func (s *AIMDScheduler) queue(orderingKey string) *aimdQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queues == nil {
+		s.queues = map[string]*aimdQueue{}
+	}
+	if s.delay == 0 {
+		s.delay = s.MinDelay
+	}
+	if s.stopc == nil {
+		s.stopc = make(chan struct{})
+	}
+	q := s.queues[orderingKey]
+	if q == nil {
+		q = &aimdQueue{flushc: make(chan []*BundledMessage)}
+		s.queues[orderingKey] = q
+		s.workers.Add(1)
+		go s.runQueue(q)
+	}
+	return q
+}
+
+// runQueue is the single reader of q.flushc and, in turn, the only
+// goroutine that ever calls Publish with one of q's bundles, so
+// successive flushes of the same ordering key reach Publish one at a
+// time, in the order they were flushed, matching the ordering Add
+// promises. It exits once s.stopc is closed.
+func (s *AIMDScheduler) runQueue(q *aimdQueue) {
+	defer s.workers.Done()
+	for {
+		select {
+		case bundle := <-q.flushc:
+			s.Publish(bundle)
+			s.wg.Done()
+		case <-s.stopc:
+			return
+		}
+	}
+}
+
+func (s *AIMDScheduler) currentDelay() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delay
+}
+
+// RecordLatency reports the wall-clock time a bundle's Publish call took,
+// adjusting the delay used for future bundles.
+func (s *AIMDScheduler) RecordLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d > s.Target {
+		s.delay /= 2
+	} else {
+		s.delay += s.AdditiveStep
+	}
+	if s.delay < s.MinDelay {
+		s.delay = s.MinDelay
+	}
+	if s.delay > s.MaxDelay {
+		s.delay = s.MaxDelay
+	}
+}
+
+// Add implements PublishScheduler.
+func (s *AIMDScheduler) Add(orderingKey string, bundle interface{}, size int) error {
+	bm, ok := bundle.(*BundledMessage)
+	if !ok {
+		return fmt.Errorf("pubsub: AIMDScheduler requires a *BundledMessage, got %T", bundle)
+	}
+	q := s.queue(orderingKey)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.paused {
+		return ErrPublishingPaused{OrderingKey: orderingKey}
+	}
+	q.pending = append(q.pending, bm)
+	q.pendingBytes += size
+	if (s.CountThreshold > 0 && len(q.pending) >= s.CountThreshold) ||
+		(s.ByteThreshold > 0 && q.pendingBytes >= s.ByteThreshold) {
+		s.flushLocked(q)
+		return nil
+	}
+	if q.timer == nil {
+		q.timer = time.AfterFunc(s.currentDelay(), func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			s.flushLocked(q)
+		})
+	}
+	return nil
+}
+
+// flushLocked hands q's pending bundle, if any, to q's worker goroutine
+// (started by s.queue) so that flushing one ordering key's queue (in
+// particular, a Publish call arriving through Publisher.publishBundled,
+// which holds Publisher.mu) never blocks on another key's Publish call.
+// Because q.flushc is unbuffered and has a single reader, this also
+// serializes successive flushes of q against each other: flushLocked does
+// not return until the worker has accepted the bundle, and the worker
+// never accepts a new one until its previous call to Publish has
+// returned. Callers must hold q.mu.
+func (s *AIMDScheduler) flushLocked(q *aimdQueue) {
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if len(q.pending) == 0 {
+		return
+	}
+	bundle := q.pending
+	q.pending = nil
+	q.pendingBytes = 0
+	s.wg.Add(1)
+	q.flushc <- bundle
+}
+
+// Pause implements PublishScheduler.
+func (s *AIMDScheduler) Pause(orderingKey string) {
+	q := s.queue(orderingKey)
+	q.mu.Lock()
+	q.paused = true
+	q.mu.Unlock()
+}
+
+// Resume implements PublishScheduler.
+func (s *AIMDScheduler) Resume(orderingKey string) {
+	q := s.queue(orderingKey)
+	q.mu.Lock()
+	q.paused = false
+	q.mu.Unlock()
+}
+
+// IsPaused implements PublishScheduler.
+func (s *AIMDScheduler) IsPaused(orderingKey string) bool {
+	q := s.queue(orderingKey)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.paused
+}
+
+// Flush implements PublishScheduler.
+func (s *AIMDScheduler) Flush() {
+	s.mu.Lock()
+	queues := make([]*aimdQueue, 0, len(s.queues))
+	for _, q := range s.queues {
+		queues = append(queues, q)
+	}
+	s.mu.Unlock()
+	for _, q := range queues {
+		q.mu.Lock()
+		s.flushLocked(q)
+		q.mu.Unlock()
+	}
+	s.wg.Wait()
+}
+
+// FlushAndStop implements PublishScheduler.
+func (s *AIMDScheduler) FlushAndStop() {
+	s.Flush()
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopc)
+	}
+	s.mu.Unlock()
+	s.workers.Wait()
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"time"
+)
+
+// PartitionStickyScheduler is a PublishScheduler for Pub/Sub Lite-style
+// topics, where messages are ultimately delivered over a small, fixed
+// number of partitions and every message sharing an ordering key must land
+// on the same partition. Rather than keeping one pending bundle per
+// ordering key, as the default scheduler does, it hashes each ordering key
+// onto one of NumPartitions logical workers up front and keeps a single
+// pending bundle per partition, so unrelated keys that land on the same
+// partition are batched together instead of competing for separate
+// timers.
+//
+// Messages without an ordering key are distributed round-robin across
+// partitions rather than all landing on partition 0.
+type PartitionStickyScheduler struct {
+	// Publish sends a bundle of messages that have all been routed to the
+	// same partition. Messages in the bundle may carry different (or no)
+	// ordering keys; callers that care about per-partition order should
+	// route messages sharing a key to a single ordering key and rely on
+	// the scheduler's per-key sequencing within a partition's bundle.
+	Publish func(partition int, bundle []*BundledMessage)
+
+	// NumPartitions is the number of logical workers to spread messages
+	// across.
+	NumPartitions int
+
+	// CountThreshold and ByteThreshold flush a partition's pending bundle
+	// once either is reached.
+	CountThreshold int
+	ByteThreshold  int
+
+	// DelayThreshold flushes a partition's pending bundle this long after
+	// its first message was added, regardless of size.
+	DelayThreshold time.Duration
+
+	mu         sync.Mutex
+	partitions []*partitionQueue
+	next       int // round-robin cursor for unkeyed messages
+	pausedKeys map[string]bool
+	stopc      chan struct{}  // closed by FlushAndStop to tell partition workers to exit
+	stopped    bool           // guards against closing stopc twice
+	wg         sync.WaitGroup // outstanding calls to Publish dispatched by flushLocked
+	workers    sync.WaitGroup // running per-partition worker goroutines
+}
+
+type partitionQueue struct {
+	mu           sync.Mutex
+	pending      []*BundledMessage
+	pendingBytes int
+	timer        *time.Timer
+	flushc       chan []*BundledMessage // flushed bundles, handed off to this partition's worker
+}
+
+func (s *PartitionStickyScheduler) partitionFor(orderingKey string) int {
+	n := s.NumPartitions
+	if n <= 0 {
+		n = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if orderingKey == "" {
+		idx := s.next % n
+		s.next++
+		return idx
+	}
+	return int(crc32.ChecksumIEEE([]byte(orderingKey))) % n
+}
+
+func (s *PartitionStickyScheduler) queue(idx int) *partitionQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.partitions == nil {
+		n := s.NumPartitions
+		if n <= 0 {
+			n = 1
+		}
+		s.partitions = make([]*partitionQueue, n)
+	}
+	if s.stopc == nil {
+		s.stopc = make(chan struct{})
+	}
+	q := s.partitions[idx]
+	if q == nil {
+		q = &partitionQueue{flushc: make(chan []*BundledMessage)}
+		s.partitions[idx] = q
+		s.workers.Add(1)
+		go s.runQueue(idx, q)
+	}
+	return q
+}
+
+// runQueue is the single reader of q.flushc and, in turn, the only
+// goroutine that ever calls Publish with one of q's bundles, so
+// successive flushes of the same partition reach Publish one at a time,
+// in the order they were flushed. It exits once s.stopc is closed.
+func (s *PartitionStickyScheduler) runQueue(idx int, q *partitionQueue) {
+	defer s.workers.Done()
+	for {
+		select {
+		case bundle := <-q.flushc:
+			s.Publish(idx, bundle)
+			s.wg.Done()
+		case <-s.stopc:
+			return
+		}
+	}
+}
+
+// Add implements PublishScheduler.
+func (s *PartitionStickyScheduler) Add(orderingKey string, bundle interface{}, size int) error {
+	bm, ok := bundle.(*BundledMessage)
+	if !ok {
+		return fmt.Errorf("pubsub: PartitionStickyScheduler requires a *BundledMessage, got %T", bundle)
+	}
+	if orderingKey != "" && s.IsPaused(orderingKey) {
+		return ErrPublishingPaused{OrderingKey: orderingKey}
+	}
+
+	idx := s.partitionFor(orderingKey)
+	q := s.queue(idx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, bm)
+	q.pendingBytes += size
+	if (s.CountThreshold > 0 && len(q.pending) >= s.CountThreshold) ||
+		(s.ByteThreshold > 0 && q.pendingBytes >= s.ByteThreshold) {
+		s.flushLocked(idx, q)
+		return nil
+	}
+	if q.timer == nil && s.DelayThreshold > 0 {
+		q.timer = time.AfterFunc(s.DelayThreshold, func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			s.flushLocked(idx, q)
+		})
+	}
+	return nil
+}
+
+// flushLocked hands q's pending bundle, if any, to q's worker goroutine
+// (started by s.queue) so that flushing one partition's queue never
+// blocks on another partition's Publish call. Because q.flushc is
+// unbuffered and has a single reader, this also serializes successive
+// flushes of q against each other: flushLocked does not return until the
+// worker has accepted the bundle, and the worker never accepts a new one
+// until its previous call to Publish has returned. Callers must hold
+// q.mu.
+func (s *PartitionStickyScheduler) flushLocked(idx int, q *partitionQueue) {
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if len(q.pending) == 0 {
+		return
+	}
+	bundle := q.pending
+	q.pending = nil
+	q.pendingBytes = 0
+	s.wg.Add(1)
+	q.flushc <- bundle
+}
+
+// Pause implements PublishScheduler. Pausing only blocks future Add calls
+// for orderingKey; it does not affect other keys already batched into the
+// same partition's pending bundle.
+func (s *PartitionStickyScheduler) Pause(orderingKey string) {
+	if orderingKey == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pausedKeys == nil {
+		s.pausedKeys = map[string]bool{}
+	}
+	s.pausedKeys[orderingKey] = true
+}
+
+// Resume implements PublishScheduler.
+func (s *PartitionStickyScheduler) Resume(orderingKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pausedKeys, orderingKey)
+}
+
+// IsPaused implements PublishScheduler.
+func (s *PartitionStickyScheduler) IsPaused(orderingKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pausedKeys[orderingKey]
+}
+
+// Flush implements PublishScheduler.
+func (s *PartitionStickyScheduler) Flush() {
+	s.mu.Lock()
+	queues := append([]*partitionQueue(nil), s.partitions...)
+	s.mu.Unlock()
+	for idx, q := range queues {
+		if q == nil {
+			continue
+		}
+		q.mu.Lock()
+		s.flushLocked(idx, q)
+		q.mu.Unlock()
+	}
+	s.wg.Wait()
+}
+
+// FlushAndStop implements PublishScheduler.
+func (s *PartitionStickyScheduler) FlushAndStop() {
+	s.Flush()
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopc)
+	}
+	s.mu.Unlock()
+	s.workers.Wait()
+}
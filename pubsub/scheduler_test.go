@@ -0,0 +1,228 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAIMDSchedulerFlushDoesNotBlockAddAndWaitsForPublish(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var published []*BundledMessage
+	s := &AIMDScheduler{
+		Publish: func(bundle []*BundledMessage) {
+			started <- struct{}{}
+			<-release
+			published = append(published, bundle...)
+		},
+		CountThreshold: 1,
+		MinDelay:       time.Hour,
+		MaxDelay:       time.Hour,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if err := s.Add("", &BundledMessage{}, 1); err != nil {
+			t.Error(err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Add blocked on the dispatched Publish call")
+	}
+	<-started
+
+	flushed := make(chan struct{})
+	go func() {
+		s.Flush()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		t.Fatal("Flush returned before the in-flight Publish call completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("Flush did not wait for the dispatched Publish call to complete")
+	}
+	if len(published) != 1 {
+		t.Errorf("got %d published bundles, want 1", len(published))
+	}
+}
+
+func TestAIMDSchedulerSerializesFlushesPerKey(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	s := &AIMDScheduler{
+		Publish: func(bundle []*BundledMessage) {
+			if bundle[0].Msg.ID == "1" {
+				<-release // block the first flush until the second has been added
+			}
+			mu.Lock()
+			order = append(order, bundle[0].Msg.ID)
+			mu.Unlock()
+		},
+		CountThreshold: 1,
+		MinDelay:       time.Hour,
+		MaxDelay:       time.Hour,
+	}
+
+	if err := s.Add("key", &BundledMessage{Msg: &Message{ID: "1"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	addDone := make(chan struct{})
+	go func() {
+		if err := s.Add("key", &BundledMessage{Msg: &Message{ID: "2"}}, 1); err != nil {
+			t.Error(err)
+		}
+		close(addDone)
+	}()
+
+	select {
+	case <-addDone:
+		t.Fatal("second Add for the same ordering key returned before the first flush's Publish call completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-addDone:
+	case <-time.After(time.Second):
+		t.Fatal("second Add did not return once the first flush's Publish call completed")
+	}
+
+	s.Flush()
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"1", "2"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("publish order = %v, want %v", order, want)
+	}
+}
+
+func TestPartitionStickySchedulerFlushDoesNotBlockAddAndWaitsForPublish(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var published []*BundledMessage
+	s := &PartitionStickyScheduler{
+		Publish: func(partition int, bundle []*BundledMessage) {
+			started <- struct{}{}
+			<-release
+			published = append(published, bundle...)
+		},
+		NumPartitions:  1,
+		CountThreshold: 1,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if err := s.Add("", &BundledMessage{}, 1); err != nil {
+			t.Error(err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Add blocked on the dispatched Publish call")
+	}
+	<-started
+
+	flushed := make(chan struct{})
+	go func() {
+		s.Flush()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		t.Fatal("Flush returned before the in-flight Publish call completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("Flush did not wait for the dispatched Publish call to complete")
+	}
+	if len(published) != 1 {
+		t.Errorf("got %d published bundles, want 1", len(published))
+	}
+}
+
+func TestPartitionStickySchedulerSerializesFlushesPerPartition(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	s := &PartitionStickyScheduler{
+		Publish: func(partition int, bundle []*BundledMessage) {
+			if bundle[0].Msg.ID == "1" {
+				<-release // block the first flush until the second has been added
+			}
+			mu.Lock()
+			order = append(order, bundle[0].Msg.ID)
+			mu.Unlock()
+		},
+		NumPartitions:  1,
+		CountThreshold: 1,
+	}
+
+	if err := s.Add("key", &BundledMessage{Msg: &Message{ID: "1"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	addDone := make(chan struct{})
+	go func() {
+		if err := s.Add("key", &BundledMessage{Msg: &Message{ID: "2"}}, 1); err != nil {
+			t.Error(err)
+		}
+		close(addDone)
+	}()
+
+	select {
+	case <-addDone:
+		t.Fatal("second Add for the same partition returned before the first flush's Publish call completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-addDone:
+	case <-time.After(time.Second):
+		t.Fatal("second Add did not return once the first flush's Publish call completed")
+	}
+
+	s.Flush()
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"1", "2"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("publish order = %v, want %v", order, want)
+	}
+}
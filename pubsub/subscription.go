@@ -0,0 +1,83 @@
+// Copyright 2016 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// ReceiveSettings configure the Receive method on a Subscription.
+type ReceiveSettings struct {
+	// MaxExtension is the maximum period for which the Subscription should
+	// automatically extend the ack deadline for each message.
+	MaxExtension time.Duration
+
+	// MaxOutstandingMessages is the maximum number of unprocessed messages
+	// (unacknowledged but not yet expired) Receive will process at a time.
+	MaxOutstandingMessages int
+
+	// MaxOutstandingBytes is the maximum size of unprocessed messages
+	// Receive will process at a time.
+	MaxOutstandingBytes int
+
+	// NackHandler, if set, is invoked whenever Message.Nack is called on a
+	// message delivered by Receive, in place of NACKing the message to the
+	// server. See the NackHandler documentation for when this is needed
+	// (most notably, the Pub/Sub Lite compatibility layer, where the
+	// server has no NACK to send) and what returning an error from it
+	// does. When NackHandler is set, MaxExtension and the rest of the
+	// per-message ack deadline machinery are not used, since there is no
+	// server-side lease to extend.
+	NackHandler NackHandler
+}
+
+// Subscription is a reference to a PubSub subscription.
+type Subscription struct {
+	c    *Client
+	name string
+
+	// ReceiveSettings configures the Receive method. Changes must be made
+	// before the first call to Receive.
+	ReceiveSettings ReceiveSettings
+}
+
+// Receive calls f with the outstanding messages from the subscription.
+// It blocks until ctx is done, or the service returns a non-retryable
+// error, or a ReceiveSettings.NackHandler returns a non-nil error.
+//
+// The standard way to terminate a call to Receive is to cancel its context.
+func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Message)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var handlerErr error
+	setHandlerErr := func(err error) {
+		if handlerErr == nil {
+			handlerErr = err
+			cancel()
+		}
+	}
+
+	err := s.pull(ctx, func(ctx context.Context, m *Message) {
+		m.settings = &s.ReceiveSettings
+		m.errc = setHandlerErr
+		f(ctx, m)
+	})
+	if handlerErr != nil {
+		return handlerErr
+	}
+	return err
+}
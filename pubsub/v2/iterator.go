@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/api/iterator"
+)
+
+// Pull returns a MessageIterator that delivers messages from the
+// subscription one at a time, as an alternative to the callback-based
+// Receive.
+//
+// Receive is a good fit for workers that process every message the same
+// way, but it is awkward for code that needs to interleave message
+// consumption with other blocking work on a single goroutine, such as a
+// database transaction, a batch write to another sink, or a
+// request/response RPC. Pull is meant for that style of caller.
+//
+// Pull is built on top of Receive, so it shares the same flow-control
+// accounting (ReceiveSettings.MaxOutstandingMessages and
+// MaxOutstandingBytes) and the same lease-extension behavior
+// (ReceiveSettings.MaxExtension) as the callback path; the two entry
+// points draw from the same leased-message stream, so metrics and
+// behavior are consistent whichever one a caller uses.
+//
+// Callers must call MessageIterator.Stop when done with the iterator.
+func (s *Subscriber) Pull(ctx context.Context) (*MessageIterator, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &MessageIterator{
+		cancel: cancel,
+		msgc:   make(chan *Message),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(it.done)
+		err := s.Receive(ctx, it.deliver)
+		it.mu.Lock()
+		it.err = err
+		it.mu.Unlock()
+	}()
+	return it, nil
+}
+
+// MessageIterator delivers messages from a subscription synchronously, for
+// callers that prefer a pull loop over the callback style of
+// Subscriber.Receive. Obtain one with Subscriber.Pull.
+//
+// MessageIterator is not safe for concurrent use by multiple goroutines.
+type MessageIterator struct {
+	cancel context.CancelFunc
+	msgc   chan *Message
+	done   chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	stopped bool
+}
+
+// Next returns the next message, blocking until one is available. It
+// returns iterator.Done once the iterator has been stopped (via Stop or by
+// the cancellation of the context passed to Pull) and all leased messages
+// have been drained.
+//
+// The returned Message's Ack and Nack methods behave exactly as they do
+// when delivered through Receive's callback.
+func (it *MessageIterator) Next() (*Message, error) {
+	select {
+	case m, ok := <-it.msgc:
+		if !ok {
+			return nil, it.stopErr()
+		}
+		return m, nil
+	case <-it.done:
+		// The receive loop has exited; drain any messages it had already
+		// queued before reporting completion.
+		select {
+		case m, ok := <-it.msgc:
+			if ok {
+				return m, nil
+			}
+		default:
+		}
+		return nil, it.stopErr()
+	}
+}
+
+// deliver hands m to a Next call, nacking it instead if ctx is done (the
+// iterator was stopped, or the caller's context was canceled) before a
+// Next call claims it, so the server redelivers m to another puller.
+func (it *MessageIterator) deliver(ctx context.Context, m *Message) {
+	select {
+	case it.msgc <- m:
+	case <-ctx.Done():
+		m.Nack()
+	}
+}
+
+func (it *MessageIterator) stopErr() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.err != nil {
+		return it.err
+	}
+	return iterator.Done
+}
+
+// Stop cancels the iterator's underlying Receive call and waits for it to
+// return. After Stop, subsequent calls to Next return iterator.Done (or
+// the error that caused Receive to exit). Stop may be called more than
+// once, and concurrently; every call waits for Receive to return, not just
+// the first.
+func (it *MessageIterator) Stop() {
+	it.mu.Lock()
+	if !it.stopped {
+		it.stopped = true
+		it.cancel()
+	}
+	it.mu.Unlock()
+
+	<-it.done
+}
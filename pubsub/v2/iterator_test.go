@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+// newTestIterator builds a MessageIterator whose cancel closes done, as if
+// a fast-exiting Receive loop had already returned.
+func newTestIterator() *MessageIterator {
+	done := make(chan struct{})
+	it := &MessageIterator{msgc: make(chan *Message), done: done}
+	it.cancel = func() { close(done) }
+	return it
+}
+
+func TestMessageIteratorNextAfterStop(t *testing.T) {
+	it := newTestIterator()
+	it.Stop()
+	if _, err := it.Next(); err != iterator.Done {
+		t.Errorf("Next() after Stop = %v, want iterator.Done", err)
+	}
+}
+
+func TestMessageIteratorNextSurfacesReceiveError(t *testing.T) {
+	done := make(chan struct{})
+	it := &MessageIterator{msgc: make(chan *Message), done: done, cancel: func() {}}
+	wantErr := errors.New("boom")
+	it.mu.Lock()
+	it.err = wantErr
+	it.mu.Unlock()
+	close(done)
+
+	if _, err := it.Next(); err != wantErr {
+		t.Errorf("Next() = %v, want %v", err, wantErr)
+	}
+}
+
+// TestMessageIteratorStopWaitsOnEveryCall exercises the case where Stop is
+// called twice concurrently: the second caller must wait for the receive
+// loop to exit too, not just the one that happened to see !it.stopped
+// first.
+func TestMessageIteratorStopWaitsOnEveryCall(t *testing.T) {
+	done := make(chan struct{})
+	it := &MessageIterator{msgc: make(chan *Message), done: done}
+	it.cancel = func() {} // canceling doesn't synchronously close done
+
+	returned := make([]chan struct{}, 2)
+	for i := range returned {
+		returned[i] = make(chan struct{})
+		go func(ch chan struct{}) {
+			it.Stop()
+			close(ch)
+		}(returned[i])
+	}
+	time.Sleep(20 * time.Millisecond) // let both calls reach Stop
+
+	for i, ch := range returned {
+		select {
+		case <-ch:
+			t.Fatalf("Stop call %d returned before done was closed", i)
+		default:
+		}
+	}
+
+	close(done)
+	for i, ch := range returned {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatalf("Stop call %d did not return after done was closed", i)
+		}
+	}
+}
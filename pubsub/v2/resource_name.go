@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+)
+
+// resourceConfig holds the per-call configuration used to resolve a short
+// resource ID to a fully-qualified resource name.
+type resourceConfig struct {
+	project string
+}
+
+// ResourceOption configures how Client resolves a short resource ID, such
+// as the one passed to TopicName or CreateTopic, into a fully-qualified
+// resource name.
+type ResourceOption func(*resourceConfig)
+
+// WithProject resolves a short resource ID against projectID instead of
+// the project the Client was created for. Use it to operate on resources,
+// such as a public topic, that live in a different project.
+func WithProject(projectID string) ResourceOption {
+	return func(c *resourceConfig) { c.project = projectID }
+}
+
+// TopicName returns the fully-qualified name of the topic with the given
+// ID in the client's project. If id is already a fully-qualified name
+// ("projects/<proj>/topics/<id>"), it is returned unchanged, unless it
+// names a different project than the client's, in which case it is
+// rejected unless opts supplies a matching WithProject. Use WithProject to
+// resolve id against a project other than the client's, such as a public
+// topic.
+func (c *Client) TopicName(id string, opts ...ResourceOption) (string, error) {
+	return c.resolveName("topics", id, opts...)
+}
+
+// SubscriptionName returns the fully-qualified name of the subscription
+// with the given ID in the client's project, with the same cross-project
+// validation as TopicName.
+func (c *Client) SubscriptionName(id string, opts ...ResourceOption) (string, error) {
+	return c.resolveName("subscriptions", id, opts...)
+}
+
+// SnapshotName returns the fully-qualified name of the snapshot with the
+// given ID in the client's project, with the same cross-project
+// validation as TopicName.
+func (c *Client) SnapshotName(id string, opts ...ResourceOption) (string, error) {
+	return c.resolveName("snapshots", id, opts...)
+}
+
+// resolveName resolves id, a short ID or a fully-qualified resource name,
+// against the project configured by opts (the Client's project, unless
+// overridden with WithProject). A fully-qualified id that names a
+// different project is rejected, so cross-project operations require an
+// explicit WithProject rather than happening by accident.
+func (c *Client) resolveName(collection, id string, opts ...ResourceOption) (string, error) {
+	return resolveResourceName(c.projectID, collection, id, opts...)
+}
+
+// resolveResourceName is the project-agnostic logic behind
+// Client.resolveName, split out so it can be tested without a Client.
+func resolveResourceName(project, collection, id string, opts ...ResourceOption) (string, error) {
+	cfg := resourceConfig{project: project}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if !strings.Contains(id, "/") {
+		return fmt.Sprintf("projects/%s/%s/%s", cfg.project, collection, id), nil
+	}
+	parts := strings.Split(id, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != collection {
+		return "", fmt.Errorf("pubsub: malformed %s name %q", singular(collection), id)
+	}
+	if parts[1] != cfg.project {
+		return "", fmt.Errorf("pubsub: %s %q belongs to project %q, not %q; pass WithProject(%q) to operate across projects",
+			singular(collection), id, parts[1], cfg.project, parts[1])
+	}
+	return id, nil
+}
+
+func singular(collection string) string {
+	return strings.TrimSuffix(collection, "s")
+}
+
+// CreateTopic creates a topic with the given ID or fully-qualified name in
+// the client's project, filling in pbTopic.Name. Use WithProject to create
+// the topic in a different project than the client's.
+func (c *Client) CreateTopic(ctx context.Context, id string, pbTopic *pb.Topic, opts ...ResourceOption) (*pb.Topic, error) {
+	name, err := c.resolveName("topics", id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if pbTopic == nil {
+		pbTopic = &pb.Topic{}
+	}
+	pbTopic.Name = name
+	return c.TopicAdminClient.CreateTopic(ctx, pbTopic)
+}
+
+// GetSubscription fetches the configuration of the subscription with the
+// given ID or fully-qualified name in the client's project.
+func (c *Client) GetSubscription(ctx context.Context, id string, opts ...ResourceOption) (*pb.Subscription, error) {
+	name, err := c.resolveName("subscriptions", id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.SubscriptionAdminClient.GetSubscription(ctx, &pb.GetSubscriptionRequest{Subscription: name})
+}
+
+// DeleteSnapshot deletes the snapshot with the given ID or fully-qualified
+// name in the client's project.
+func (c *Client) DeleteSnapshot(ctx context.Context, id string, opts ...ResourceOption) error {
+	name, err := c.resolveName("snapshots", id, opts...)
+	if err != nil {
+		return err
+	}
+	return c.SubscriptionAdminClient.DeleteSnapshot(ctx, &pb.DeleteSnapshotRequest{Snapshot: name})
+}
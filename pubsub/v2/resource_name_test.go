@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import "testing"
+
+func TestResolveResourceName(t *testing.T) {
+	got, err := resolveResourceName("proj1", "topics", "my-topic")
+	if err != nil {
+		t.Fatalf("short id: got err %v, want nil", err)
+	}
+	if want := "projects/proj1/topics/my-topic"; got != want {
+		t.Errorf("short id: got %q, want %q", got, want)
+	}
+
+	fq := "projects/proj1/topics/my-topic"
+	got, err = resolveResourceName("proj1", "topics", fq)
+	if err != nil || got != fq {
+		t.Errorf("fully-qualified id: got (%q, %v), want (%q, nil)", got, err, fq)
+	}
+
+	if _, err := resolveResourceName("proj1", "topics", "projects/other/topics/my-topic"); err == nil {
+		t.Error("cross-project id without WithProject: got nil err, want error")
+	}
+
+	got, err = resolveResourceName("proj1", "topics", "projects/other/topics/my-topic", WithProject("other"))
+	if err != nil || got != "projects/other/topics/my-topic" {
+		t.Errorf("cross-project id with WithProject: got (%q, %v), want (%q, nil)", got, err, "projects/other/topics/my-topic")
+	}
+
+	if _, err := resolveResourceName("proj1", "topics", "projects/proj1/subscriptions/my-sub"); err == nil {
+		t.Error("malformed (wrong collection) id: got nil err, want error")
+	}
+}
+
+func TestClientTopicNameValidatesProject(t *testing.T) {
+	c := &Client{projectID: "proj1"}
+
+	got, err := c.TopicName("my-topic")
+	if err != nil {
+		t.Fatalf("short id: got err %v, want nil", err)
+	}
+	if want := "projects/proj1/topics/my-topic"; got != want {
+		t.Errorf("short id: got %q, want %q", got, want)
+	}
+
+	if _, err := c.TopicName("projects/other/topics/my-topic"); err == nil {
+		t.Error("cross-project id without WithProject: got nil err, want error")
+	}
+
+	got, err = c.TopicName("projects/other/topics/my-topic", WithProject("other"))
+	if err != nil || got != "projects/other/topics/my-topic" {
+		t.Errorf("cross-project id with WithProject: got (%q, %v), want (%q, nil)", got, err, "projects/other/topics/my-topic")
+	}
+}